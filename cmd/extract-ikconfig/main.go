@@ -15,7 +15,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	kernel, err := ikconfig.NewKernelConfig(file, ikconfig.KERNEL_COMPRESSION_TYPE_BZIP2)
+	kernel, err := ikconfig.NewKernelConfig(file, ikconfig.KERNEL_COMPRESSION_TYPE_UNKNOWN)
 	if err != nil {
 		fmt.Printf("error creating kernel config: %v\n", err)
 		os.Exit(1)
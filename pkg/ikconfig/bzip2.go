@@ -0,0 +1,22 @@
+package ikconfig
+
+import (
+	"compress/bzip2"
+	"io"
+)
+
+type bzip2Decompressor struct{}
+
+func (bzip2Decompressor) Name() string { return "bzip2" }
+
+func (bzip2Decompressor) Magic() []byte { return []byte{0x42, 0x5a, 0x68} }
+
+func (bzip2Decompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	// compress/bzip2 only exposes an io.Reader, so wrap it to satisfy
+	// Decompressor's io.ReadCloser return type.
+	return io.NopCloser(bzip2.NewReader(r)), nil
+}
+
+func init() {
+	RegisterDecompressor(KERNEL_COMPRESSION_TYPE_BZIP2, bzip2Decompressor{})
+}
@@ -0,0 +1,147 @@
+package ikconfig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+	"strings"
+)
+
+type KernelConfigEnabled int
+
+const (
+	KERNEL_CONFIG_BUILT_IN KernelConfigEnabled = iota // =y
+	KERNEL_CONFIG_LOADABLE                            // =m
+	KERNEL_CONFIG_DISABLED                            // =n, or "# CONFIG_FOO is not set"
+)
+
+// KernelConfigValue is a single kernel config entry, keeping both the raw
+// text after the '=' and a best-effort typed interpretation of it.
+type KernelConfigValue struct {
+	Raw    string              // the unparsed right-hand side, e.g. `"gcc (GCC) 13.2.1"` or "4096"; the whole line for "# CONFIG_FOO is not set"
+	Kind   KernelConfigEnabled // whether the option is built in, a module, or disabled
+	String string              // Raw with quotes stripped and escapes resolved, for string options
+	Int    int64               // Raw parsed as a decimal or hex integer, for integer options
+	Bool   bool                // true for =y and =m, false otherwise
+}
+
+// KernelConfigMap maps a CONFIG_* name to its value, as parsed out of a
+// kernel's embedded config.
+type KernelConfigMap map[string]KernelConfigValue
+
+func (k *KernelConfigMap) Get(key string) (KernelConfigValue, error) {
+	val, ok := (*k)[key]
+	if !ok {
+		return KernelConfigValue{}, fmt.Errorf("key %q not found", key)
+	}
+	return val, nil
+}
+
+// Enabled reports whether key is present and not disabled: built in (=y),
+// a loadable module (=m), or set to a string or integer value.
+func (k *KernelConfigMap) Enabled(key string) bool {
+	val, ok := (*k)[key]
+	return ok && val.Kind != KERNEL_CONFIG_DISABLED
+}
+
+// IsModule reports whether key is built as a loadable module (=m).
+func (k *KernelConfigMap) IsModule(key string) bool {
+	val, ok := (*k)[key]
+	return ok && val.Kind == KERNEL_CONFIG_LOADABLE
+}
+
+// Iter ranges over every config entry, including ones explicitly recorded
+// as disabled via "# CONFIG_FOO is not set".
+func (k *KernelConfigMap) Iter() iter.Seq2[string, KernelConfigValue] {
+	return func(yield func(string, KernelConfigValue) bool) {
+		for key, val := range *k {
+			if !yield(key, val) {
+				return
+			}
+		}
+	}
+}
+
+// parseKernelConfigMap reads a ".config"-style stream - "KEY=VALUE" lines,
+// "# CONFIG_FOO is not set" comments recording an explicitly disabled
+// option, blank lines, and ordinary "#" comments - into a KernelConfigMap.
+func parseKernelConfigMap(r io.Reader) (*KernelConfigMap, error) {
+	configMap := KernelConfigMap{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		key, val, ok := parseKernelConfigLine(line)
+		if !ok {
+			continue
+		}
+		configMap[key] = val
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error scanning kernel config: %w", err)
+	}
+
+	return &configMap, nil
+}
+
+// parseKernelConfigLine parses a single line of a kernel config into a
+// key/value pair. It returns ok=false for lines that aren't a config entry,
+// e.g. an ordinary comment.
+func parseKernelConfigLine(line string) (string, KernelConfigValue, bool) {
+	if strings.HasPrefix(line, "#") {
+		key, isDisabled := strings.CutSuffix(strings.TrimSpace(line[1:]), "is not set")
+		if !isDisabled {
+			return "", KernelConfigValue{}, false
+		}
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return "", KernelConfigValue{}, false
+		}
+		return key, KernelConfigValue{Raw: line, Kind: KERNEL_CONFIG_DISABLED}, true
+	}
+
+	key, raw, ok := strings.Cut(line, "=")
+	if !ok {
+		return "", KernelConfigValue{}, false
+	}
+	key = strings.TrimSpace(key)
+	raw = strings.TrimSpace(raw)
+	if key == "" {
+		return "", KernelConfigValue{}, false
+	}
+
+	val := KernelConfigValue{Raw: raw, String: raw}
+
+	switch raw {
+	case "y":
+		val.Kind, val.Bool = KERNEL_CONFIG_BUILT_IN, true
+		return key, val, true
+	case "m":
+		val.Kind, val.Bool = KERNEL_CONFIG_LOADABLE, true
+		return key, val, true
+	case "n":
+		val.Kind = KERNEL_CONFIG_DISABLED
+		return key, val, true
+	}
+
+	if len(raw) >= 2 && strings.HasPrefix(raw, `"`) && strings.HasSuffix(raw, `"`) {
+		if unquoted, err := strconv.Unquote(raw); err == nil {
+			val.String = unquoted
+		} else {
+			val.String = strings.Trim(raw, `"`)
+		}
+		return key, val, true
+	}
+
+	if n, err := strconv.ParseInt(raw, 0, 64); err == nil {
+		val.Int = n
+	}
+
+	return key, val, true
+}
@@ -0,0 +1,150 @@
+package ikconfig
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ContainerKind identifies the outer wrapper a kernel image ships in, as
+// distinct from the compression algorithm used for its payload. Real
+// distro kernels are almost never a raw compressed stream - they're a
+// bzImage or an EFI stub PE/COFF file with the compressed payload buried
+// inside - so this has to be resolved before any compression magic search.
+type ContainerKind int
+
+const (
+	// ContainerNone means the file is already a raw compressed (or
+	// uncompressed) payload with no bzImage/PE/ELF wrapper around it.
+	ContainerNone ContainerKind = iota
+	// ContainerBzImage is the x86 boot sector + setup header + compressed
+	// payload format produced by the kernel build, as found in most
+	// /boot/vmlinuz-* files.
+	ContainerBzImage
+	// ContainerEFI is an EFI stub PE/COFF wrapper around the same payload.
+	ContainerEFI
+	// ContainerELF is an uncompressed vmlinux ELF with IKCFG_ST embedded
+	// directly - no decompression needed at all.
+	ContainerELF
+)
+
+func (c ContainerKind) String() string {
+	switch c {
+	case ContainerBzImage:
+		return "bzImage"
+	case ContainerEFI:
+		return "EFI"
+	case ContainerELF:
+		return "ELF"
+	default:
+		return "none"
+	}
+}
+
+var (
+	bzImageHdrSMagic = []byte{'H', 'd', 'r', 'S'}
+	peMagic          = []byte{'M', 'Z'}
+	elfMagic         = []byte{0x7f, 'E', 'L', 'F'}
+)
+
+const (
+	// bzImageHdrSOffset is where the setup header's "HdrS" signature
+	// lives, per Documentation/arch/x86/boot.rst.
+	bzImageHdrSOffset = 0x202
+	// bzImageSetupSectsOffset holds setup_sects, the size of the setup
+	// code in 512-byte sectors (not counting the boot sector itself).
+	bzImageSetupSectsOffset = 0x1f1
+	// bzImageDefaultSetupSects is the historical setup_sects value used
+	// when the field is stored as 0.
+	bzImageDefaultSetupSects = 4
+	sectorSize               = 512
+)
+
+// DetectContainer inspects k's file for a bzImage, EFI stub PE, or plain
+// ELF wrapper around the kernel config payload, mirroring what
+// scripts/extract-ikconfig does by trying each magic in turn, and returns
+// the byte offset at which the inner (still compressed, except for
+// ContainerELF) payload begins.
+func (k *KernelConfig) DetectContainer() (ContainerKind, int64, error) {
+	f, err := os.Open(k.path)
+	if err != nil {
+		return ContainerNone, 0, fmt.Errorf("error opening kernel file: %w", err)
+	}
+	defer f.Close()
+
+	header := make([]byte, bzImageHdrSOffset+len(bzImageHdrSMagic))
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return ContainerNone, 0, fmt.Errorf("error reading kernel header: %w", err)
+	}
+	header = header[:n]
+
+	if len(header) >= len(elfMagic) && bytes.Equal(header[:len(elfMagic)], elfMagic) {
+		return ContainerELF, 0, nil
+	}
+
+	if len(header) == cap(header) && bytes.Equal(header[bzImageHdrSOffset:], bzImageHdrSMagic) {
+		setupSects := int(header[bzImageSetupSectsOffset])
+		if setupSects == 0 {
+			setupSects = bzImageDefaultSetupSects
+		}
+		// the payload follows the boot sector (1 sector) and the setup
+		// code (setup_sects sectors).
+		return ContainerBzImage, int64(setupSects+1) * sectorSize, nil
+	}
+
+	if len(header) >= len(peMagic) && bytes.Equal(header[:len(peMagic)], peMagic) {
+		offset, err := findPEPayloadOffset(f)
+		if err != nil {
+			return ContainerNone, 0, fmt.Errorf("error locating EFI stub payload: %w", err)
+		}
+		return ContainerEFI, offset, nil
+	}
+
+	return ContainerNone, 0, nil
+}
+
+// findPEPayloadOffset walks a PE/COFF section table looking for the
+// section holding the compressed kernel payload, which the EFI stub build
+// places in .rodata.
+func findPEPayloadOffset(f *os.File) (int64, error) {
+	var dosHeader [64]byte
+	if _, err := f.ReadAt(dosHeader[:], 0); err != nil {
+		return 0, fmt.Errorf("error reading DOS header: %w", err)
+	}
+	peOffset := int64(binary.LittleEndian.Uint32(dosHeader[0x3c:0x40]))
+
+	var sig [4]byte
+	if _, err := f.ReadAt(sig[:], peOffset); err != nil {
+		return 0, fmt.Errorf("error reading PE signature: %w", err)
+	}
+	if !bytes.Equal(sig[:], []byte{'P', 'E', 0, 0}) {
+		return 0, fmt.Errorf("bad PE signature at offset %d", peOffset)
+	}
+
+	var coff [20]byte
+	if _, err := f.ReadAt(coff[:], peOffset+4); err != nil {
+		return 0, fmt.Errorf("error reading COFF header: %w", err)
+	}
+	numSections := int(binary.LittleEndian.Uint16(coff[2:4]))
+	sizeOptHeader := int64(binary.LittleEndian.Uint16(coff[16:18]))
+
+	const sectionHeaderSize = 40
+	sectionTableOffset := peOffset + 4 + 20 + sizeOptHeader
+
+	for i := 0; i < numSections; i++ {
+		var sec [sectionHeaderSize]byte
+		if _, err := f.ReadAt(sec[:], sectionTableOffset+int64(i)*sectionHeaderSize); err != nil {
+			return 0, fmt.Errorf("error reading section header %d: %w", i, err)
+		}
+		name := strings.TrimRight(string(sec[0:8]), "\x00")
+		if name == ".rodata" {
+			return int64(binary.LittleEndian.Uint32(sec[20:24])), nil
+		}
+	}
+
+	return 0, fmt.Errorf("no .rodata section found")
+}
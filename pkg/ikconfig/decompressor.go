@@ -0,0 +1,71 @@
+package ikconfig
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Decompressor describes a single kernel compression algorithm: how to
+// recognise a stream compressed with it and how to wrap it with a reader
+// that yields the decompressed bytes. Implementations register themselves
+// with RegisterDecompressor from an init() function, mirroring the pattern
+// used by image codecs in the standard library.
+type Decompressor interface {
+	// Magic returns the byte sequence identifying this algorithm at the
+	// start of a compressed stream.
+	Magic() []byte
+	// Name returns the human-readable name of the algorithm, e.g. "gzip".
+	Name() string
+	// NewReader wraps r with a decompressing io.ReadCloser.
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+var decompressors = map[KernelCompressionType]Decompressor{}
+
+// RegisterDecompressor makes d available under t. It is meant to be called
+// from the init() function of the file implementing d, and panics on a
+// duplicate registration since that can only be a programming error.
+func RegisterDecompressor(t KernelCompressionType, d Decompressor) {
+	if _, ok := decompressors[t]; ok {
+		panic(fmt.Sprintf("ikconfig: Decompressor already registered for %v", t))
+	}
+	decompressors[t] = d
+}
+
+// LookupByMagic returns the Decompressor whose magic bytes occur at the
+// start of b. It returns an error if none of the registered decompressors
+// match.
+func LookupByMagic(b []byte) (Decompressor, error) {
+	for _, d := range decompressors {
+		if bytes.HasPrefix(b, d.Magic()) {
+			return d, nil
+		}
+	}
+	return nil, fmt.Errorf("no registered decompressor matches magic %v", b)
+}
+
+// candidate pairs a registered decompressor with the offset at which its
+// magic bytes were found in a probed buffer.
+type candidate struct {
+	Type         KernelCompressionType
+	Decompressor Decompressor
+	Offset       int
+}
+
+// probeAll returns every registered decompressor whose magic occurs
+// somewhere in b, ordered by ascending offset. It is used to drive
+// trial-and-error decompression when the compression type isn't known up
+// front: the caller tries each candidate in turn, starting with the one
+// whose magic matched earliest.
+func probeAll(b []byte) []candidate {
+	var out []candidate
+	for t, d := range decompressors {
+		if idx := bytes.Index(b, d.Magic()); idx >= 0 {
+			out = append(out, candidate{Type: t, Decompressor: d, Offset: idx})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Offset < out[j].Offset })
+	return out
+}
@@ -0,0 +1,20 @@
+package ikconfig
+
+import (
+	"compress/gzip"
+	"io"
+)
+
+type gzipDecompressor struct{}
+
+func (gzipDecompressor) Name() string { return "gzip" }
+
+func (gzipDecompressor) Magic() []byte { return []byte{0x1f, 0x8b} }
+
+func (gzipDecompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+func init() {
+	RegisterDecompressor(KERNEL_COMPRESSION_TYPE_GZIP, gzipDecompressor{})
+}
@@ -5,14 +5,10 @@ package ikconfig
 
 import (
 	"bytes"
-	"compress/bzip2"
 	"compress/gzip"
 	"fmt"
 	"io"
 	"os"
-
-	"github.com/klauspost/compress/zstd"
-	"github.com/ulikunitz/xz"
 )
 
 var (
@@ -20,24 +16,6 @@ var (
 	KERNEL_CONFIG_MAGIC = []byte{'I', 'K', 'C', 'F', 'G'}
 )
 
-type KernelConfigEnabled int
-
-const (
-	KERNEL_CONFIG_BUILT_IN KernelConfigEnabled = iota // =y
-	KERNEL_CONFIG_LOADABLE                            // =m
-)
-
-// Represents the
-type KernelConfigMap map[string]string
-
-func (k *KernelConfigMap) Get(key string) (string, error) {
-	val, ok := (*k)[key]
-	if !ok {
-		return "", fmt.Errorf("key %q not found", key)
-	}
-	return val, nil
-}
-
 // All supported kernel compression types for kernel version 6.7.1
 // CONFIG_HAVE_KERNEL_GZIP
 // CONFIG_HAVE_KERNEL_BZIP2
@@ -60,12 +38,16 @@ const (
 	KERNEL_COMPRESSION_TYPE_UNKNOWN
 )
 
+// Magic returns the byte sequence identifying k's compression, delegating
+// to whichever Decompressor is registered for it. It returns nil for
+// KERNEL_COMPRESSION_TYPE_NONE, KERNEL_COMPRESSION_TYPE_UNKNOWN, or a type
+// with no registered Decompressor.
 func (k KernelCompressionType) Magic() []byte {
-	return [...][]byte{
-		{0x1f, 0x8b}, // GZIP
-		{0x5c, 0x33, 0x37, 0x35, 0x37, 0x7a, 0x58, 0x59, 0x00}, // XZ
-		{0x42, 0x5a, 0x68}, // bunzip2
-	}[k]
+	d, ok := decompressors[k]
+	if !ok {
+		return nil
+	}
+	return d.Magic()
 }
 
 type KernelConfig struct {
@@ -92,17 +74,11 @@ func (k *KernelConfig) findKernelConfigMagic() (uint, error) {
 	return SearchBytes(k.path, KERNEL_CONFIG_MAGIC)
 }
 
-func (k *KernelConfig) findCompressionMagic() (uint, error) {
-	return SearchBytes(k.path, k.compressionType.Magic())
-}
-
 // Create a new KernelConfig object based on the provided path
 // If the compression algorithm is known it can be passed to the constructor
 // to speed up the later decompression time
 // The path to the decompressed file is stored in the object
 func (k *KernelConfig) decompress() error {
-	// if the type is unknown, try to guess it
-
 	tmpdir, err := os.MkdirTemp("", "ikconfig")
 	if err != nil {
 		return fmt.Errorf("error creating temporary directory: %w", err)
@@ -115,71 +91,97 @@ func (k *KernelConfig) decompress() error {
 	}
 	defer decomp.Close()
 
-	kernel, err := os.Open(k.path)
+	containerKind, payloadOffset, err := k.DetectContainer()
 	if err != nil {
-		return fmt.Errorf("error opening kernel file: %w", err)
+		return fmt.Errorf("error detecting container format: %w", err)
 	}
 
-	// find offset of magic values
-	// pos, err := k.findMagic()
-	if err != nil {
-		return fmt.Errorf("error finding magic string for %v: %w", k.compressionType, err)
+	if containerKind == ContainerELF {
+		// a plain vmlinux ELF already has IKCFG_ST embedded uncompressed.
+		if err := os.Link(k.path, k.pathDecompressed); err != nil {
+			return fmt.Errorf("error linking decompressed file: %w", err)
+		}
+		return nil
 	}
 
-	switch k.compressionType {
-	case KERNEL_COMPRESSION_TYPE_NONE:
-		os.Link(k.path, k.pathDecompressed)
-
-	case KERNEL_COMPRESSION_TYPE_UNKNOWN:
-		// try all the compression types
-	case KERNEL_COMPRESSION_TYPE_GZIP:
-		gzipReader, err := gzip.NewReader(kernel)
-		if err != nil {
-			return fmt.Errorf("error creating gzip reader: %w", err)
-		}
-		// write the decompressed file to the decompressed path
-		_, err = io.Copy(decomp, gzipReader)
-		if err != nil {
-			return fmt.Errorf("error decompressing gzip: %w", err)
+	if k.compressionType == KERNEL_COMPRESSION_TYPE_NONE && payloadOffset == 0 {
+		if err := os.Link(k.path, k.pathDecompressed); err != nil {
+			return fmt.Errorf("error linking decompressed file: %w", err)
 		}
+		return nil
+	}
 
-	case KERNEL_COMPRESSION_TYPE_BZIP2:
-		bzipReader := bzip2.NewReader(kernel)
-		_, err := io.Copy(decomp, bzipReader)
-		if err != nil {
-			return fmt.Errorf("error decompressing bzip2: %w", err)
+	kernel, err := os.Open(k.path)
+	if err != nil {
+		return fmt.Errorf("error opening kernel file: %w", err)
+	}
+	defer kernel.Close()
+
+	if payloadOffset > 0 {
+		if _, err := kernel.Seek(payloadOffset, io.SeekStart); err != nil {
+			return fmt.Errorf("error seeking past %s container: %w", containerKind, err)
 		}
+	}
 
-	case KERNEL_COMPRESSION_TYPE_LZMA:
-	case KERNEL_COMPRESSION_TYPE_XZ:
-		buf := bytes.Buffer{}
-		_, err := io.Copy(&buf, kernel)
-		if err != nil {
-			return fmt.Errorf("error copying kernel file to buffer: %w", err)
+	if k.compressionType == KERNEL_COMPRESSION_TYPE_NONE {
+		if _, err := io.Copy(decomp, kernel); err != nil {
+			return fmt.Errorf("error copying uncompressed payload: %w", err)
 		}
+		return nil
+	}
 
-		xzReader, err := xz.NewReader(&buf)
+	if k.compressionType != KERNEL_COMPRESSION_TYPE_UNKNOWN {
+		d, ok := decompressors[k.compressionType]
+		if !ok {
+			return fmt.Errorf("unknown/unsupported compression type: %v", k.compressionType)
+		}
+		r, err := d.NewReader(kernel)
 		if err != nil {
-			return fmt.Errorf("error creating xz reader: %w", err)
+			return fmt.Errorf("error creating %s reader: %w", d.Name(), err)
 		}
-		if _, err := io.Copy(decomp, xzReader); err != nil {
-			return fmt.Errorf("error decompressing xz: %w", err)
+		defer r.Close()
+		if _, err := io.Copy(decomp, r); err != nil {
+			return fmt.Errorf("error decompressing %s: %w", d.Name(), err)
 		}
+		return nil
+	}
+
+	// Compression type is unknown: read the rest of the file from the
+	// container's payload offset, find every
+	// registered decompressor whose magic occurs in it, and try each in
+	// turn starting with the earliest matching offset until one of them
+	// succeeds.
+	data, err := io.ReadAll(kernel)
+	if err != nil {
+		return fmt.Errorf("error reading kernel file: %w", err)
+	}
+
+	candidates := probeAll(data)
+	if len(candidates) == 0 {
+		return fmt.Errorf("error detecting compression type: no registered decompressor matched")
+	}
 
-	case KERNEL_COMPRESSION_TYPE_ZSTD:
-		zstdReader, err := zstd.NewReader(kernel)
+	var lastErr error
+	for _, c := range candidates {
+		decomp.Truncate(0)
+		decomp.Seek(0, io.SeekStart)
+
+		r, err := c.Decompressor.NewReader(bytes.NewReader(data[c.Offset:]))
 		if err != nil {
-			return fmt.Errorf("error creating zstd reader: %w", err)
+			lastErr = fmt.Errorf("error creating %s reader: %w", c.Decompressor.Name(), err)
+			continue
 		}
-		if _, err := io.Copy(decomp, zstdReader); err != nil {
-			return fmt.Errorf("error decompressing zstd: %w", err)
+		_, err = io.Copy(decomp, r)
+		r.Close()
+		if err != nil {
+			lastErr = fmt.Errorf("error decompressing %s: %w", c.Decompressor.Name(), err)
+			continue
 		}
-	case KERNEL_COMPRESSION_TYPE_LZO:
-	case KERNEL_COMPRESSION_TYPE_LZ4:
-	default:
-		return fmt.Errorf("unknown/unsupported compression type: %v", k.compressionType)
+
+		k.compressionType = c.Type
+		return nil
 	}
-	return nil
+	return fmt.Errorf("error detecting compression type: all candidates failed, last error: %w", lastErr)
 }
 
 func NewKernelConfig(path string, compression KernelCompressionType) (*KernelConfig, error) {
@@ -192,39 +194,39 @@ func NewKernelConfig(path string, compression KernelCompressionType) (*KernelCon
 	}, nil
 }
 
-// Parse the provided kernel config as a map of enabled features
+// Parse the provided kernel config as a map of feature to typed value.
+// After decompressing the kernel image, the IKCFG_ST-delimited payload
+// that follows the magic is itself a gzip stream of "KEY=VALUE" lines,
+// which is gunzipped directly into the returned map without ever touching
+// disk.
 func (k *KernelConfig) ParseKernelConfig() (*KernelConfigMap, error) {
-	var configBuf bytes.Buffer
-	err := k.decompress()
-	if err != nil {
+	if err := k.decompress(); err != nil {
 		return nil, fmt.Errorf("error decompressing kernel config: %w", err)
 	}
 
-	// after the file is decompressed, get the kernel config from the end of the file
+	// after the file is decompressed, find where the embedded config
+	// payload starts: the magic itself is 8 bytes ("IKCFG_ST"), even
+	// though we only search for the first 5 of them.
 	magicOffset, err := k.findKernelConfigMagic()
 	if err != nil {
 		return nil, fmt.Errorf("error finding magic string in kernel config: %w", err)
 	}
 
-	// the config file is a zip file with the kernel config at the end
-	// extract the zip from the end and decompress it
-	configDecompressed, err := os.ReadFile(k.pathDecompressed)
+	vmlinux, err := os.Open(k.pathDecompressed)
 	if err != nil {
-		return nil, fmt.Errorf("error reading kernel config: %w", err)
+		return nil, fmt.Errorf("error opening decompressed kernel: %w", err)
 	}
-	configBuf.Write(configDecompressed[magicOffset+8:])
+	defer vmlinux.Close()
 
-	// unzip and write to file $PWD/config
-	out, err := os.Open("config")
-	if err != nil {
-		return nil, fmt.Errorf("error opening config file: %w", err)
+	if _, err := vmlinux.Seek(int64(magicOffset)+8, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("error seeking to kernel config payload: %w", err)
 	}
 
-	gzipReader, err := gzip.NewReader(&configBuf)
+	gzipReader, err := gzip.NewReader(vmlinux)
 	if err != nil {
-		return nil, fmt.Errorf("error creating gzip reader: %w", err)
+		return nil, fmt.Errorf("error creating gzip reader for kernel config: %w", err)
 	}
+	defer gzipReader.Close()
 
-	io.Copy(out, gzipReader)
-	return &KernelConfigMap{}, nil
+	return parseKernelConfigMap(gzipReader)
 }
@@ -0,0 +1,86 @@
+package ikconfig
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/pierrec/lz4/v4"
+)
+
+type lz4Decompressor struct{}
+
+func (lz4Decompressor) Name() string { return "lz4" }
+
+func (lz4Decompressor) Magic() []byte { return []byte{0x02, 0x21, 0x4c, 0x18} }
+
+// lz4LegacyBlockMax is the largest block the kernel's LZ4 "legacy" framing
+// ever emits (see lib/decompress_unlz4.c), used to size the decompression
+// buffer for each block.
+const lz4LegacyBlockMax = 8 << 20
+
+func (lz4Decompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	var magic [4]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return nil, fmt.Errorf("error reading lz4 magic: %w", err)
+	}
+	return io.NopCloser(&lz4LegacyReader{r: r}), nil
+}
+
+func init() {
+	RegisterDecompressor(KERNEL_COMPRESSION_TYPE_LZ4, lz4Decompressor{})
+}
+
+// lz4LegacyReader decompresses the kernel's "legacy" LZ4 framing: after the
+// magic number, a sequence of <uint32 length, little-endian><lz4 block>
+// pairs, terminated by a zero-length block.
+type lz4LegacyReader struct {
+	r    io.Reader
+	buf  []byte
+	pos  int
+	done bool
+}
+
+func (z *lz4LegacyReader) Read(p []byte) (int, error) {
+	for z.pos >= len(z.buf) {
+		if z.done {
+			return 0, io.EOF
+		}
+		if err := z.fill(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, z.buf[z.pos:])
+	z.pos += n
+	return n, nil
+}
+
+func (z *lz4LegacyReader) fill() error {
+	var lenBuf [4]byte
+	if _, err := io.ReadFull(z.r, lenBuf[:]); err != nil {
+		return fmt.Errorf("error reading lz4 block length: %w", err)
+	}
+
+	blockLen := binary.LittleEndian.Uint32(lenBuf[:])
+	if blockLen == 0 {
+		z.done, z.buf, z.pos = true, nil, 0
+		return nil
+	}
+	if blockLen > lz4LegacyBlockMax {
+		return fmt.Errorf("lz4 block length %d exceeds maximum %d", blockLen, lz4LegacyBlockMax)
+	}
+
+	compressed := make([]byte, blockLen)
+	if _, err := io.ReadFull(z.r, compressed); err != nil {
+		return fmt.Errorf("error reading lz4 block: %w", err)
+	}
+
+	decompressed := make([]byte, lz4LegacyBlockMax)
+	n, err := lz4.UncompressBlock(compressed, decompressed)
+	if err != nil {
+		return fmt.Errorf("error decompressing lz4 block: %w", err)
+	}
+
+	z.buf, z.pos = decompressed[:n], 0
+	return nil
+}
@@ -0,0 +1,28 @@
+package ikconfig
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ulikunitz/xz/lzma"
+)
+
+type lzmaDecompressor struct{}
+
+func (lzmaDecompressor) Name() string { return "lzma" }
+
+func (lzmaDecompressor) Magic() []byte { return []byte{0x5d, 0x00, 0x00, 0x00} }
+
+func (lzmaDecompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	lzmaReader, err := lzma.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("error creating lzma reader: %w", err)
+	}
+	// github.com/ulikunitz/xz/lzma only exposes an io.Reader, so wrap it to
+	// satisfy Decompressor's io.ReadCloser return type.
+	return io.NopCloser(lzmaReader), nil
+}
+
+func init() {
+	RegisterDecompressor(KERNEL_COMPRESSION_TYPE_LZMA, lzmaDecompressor{})
+}
@@ -0,0 +1,146 @@
+package ikconfig
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+type lzoDecompressor struct{}
+
+func (lzoDecompressor) Name() string { return "lzo" }
+
+func (lzoDecompressor) Magic() []byte {
+	return []byte{0x89, 0x4c, 0x5a, 0x4f, 0x00, 0x0d, 0x0a, 0x1a, 0x0a}
+}
+
+// lzoAdler32D and lzoAdler32C mirror the F_ADLER32_D / F_ADLER32_C bits of
+// an lzop file's flags word, which tell the kernel's own
+// lib/decompress_unlzo.c whether each block's two length words are
+// followed by a checksum of the decompressed and/or compressed payload,
+// ahead of the block data.
+const (
+	lzoAdler32D = 1 << 0
+	lzoAdler32C = 1 << 1
+)
+
+// lzoHeaderFixedLen is the fixed-size portion of an lzop header that follows
+// the 9-byte magic, as parsed by lib/decompress_unlzo.c's parse_header():
+// version(2) + lib_version(2) + version_needed(2) + method(1) + level(1) +
+// flags(4) + mode(4) + mtime_low(4) + mtime_high(4) + len_name(1). A
+// variable-length filename and a 4-byte header checksum follow.
+const lzoHeaderFixedLen = 25
+
+// lzoBlockMax is the largest block the kernel's own lib/decompress_unlzo.c
+// ever emits, used to reject a corrupt or adversarial length field before
+// it drives an allocation, mirroring lz4LegacyBlockMax.
+const lzoBlockMax = 8 << 20
+
+func (lzoDecompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	var magic [9]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return nil, fmt.Errorf("error reading lzo magic: %w", err)
+	}
+
+	var hdr [lzoHeaderFixedLen]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, fmt.Errorf("error reading lzo header: %w", err)
+	}
+	flags := binary.BigEndian.Uint32(hdr[8:12])
+
+	nameLen := int(hdr[24])
+	if nameLen > 0 {
+		if _, err := io.CopyN(io.Discard, r, int64(nameLen)); err != nil {
+			return nil, fmt.Errorf("error skipping lzo header filename: %w", err)
+		}
+	}
+
+	var checksum [4]byte
+	if _, err := io.ReadFull(r, checksum[:]); err != nil {
+		return nil, fmt.Errorf("error reading lzo header checksum: %w", err)
+	}
+
+	return io.NopCloser(&lzoBlockReader{r: r, flags: flags}), nil
+}
+
+func init() {
+	RegisterDecompressor(KERNEL_COMPRESSION_TYPE_LZO, lzoDecompressor{})
+}
+
+// lzoBlockReader decompresses the kernel's lzop block stream: a sequence of
+// <uncompressed length><compressed length>[checksums]<data> blocks
+// terminated by a zero uncompressed length, as consumed by
+// lib/decompress_unlzo.c. A block whose compressed length equals its
+// uncompressed length was stored rather than compressed.
+type lzoBlockReader struct {
+	r     io.Reader
+	flags uint32
+	buf   []byte
+	pos   int
+	done  bool
+}
+
+func (z *lzoBlockReader) Read(p []byte) (int, error) {
+	for z.pos >= len(z.buf) {
+		if z.done {
+			return 0, io.EOF
+		}
+		if err := z.fill(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, z.buf[z.pos:])
+	z.pos += n
+	return n, nil
+}
+
+func (z *lzoBlockReader) fill() error {
+	var lenBuf [4]byte
+	if _, err := io.ReadFull(z.r, lenBuf[:]); err != nil {
+		return fmt.Errorf("error reading lzo block length: %w", err)
+	}
+	dstLen := binary.BigEndian.Uint32(lenBuf[:])
+	if dstLen == 0 {
+		z.done, z.buf, z.pos = true, nil, 0
+		return nil
+	}
+	if dstLen > lzoBlockMax {
+		return fmt.Errorf("lzo decompressed block length %d exceeds maximum %d", dstLen, lzoBlockMax)
+	}
+
+	if _, err := io.ReadFull(z.r, lenBuf[:]); err != nil {
+		return fmt.Errorf("error reading lzo compressed length: %w", err)
+	}
+	srcLen := binary.BigEndian.Uint32(lenBuf[:])
+	if srcLen > lzoBlockMax {
+		return fmt.Errorf("lzo compressed block length %d exceeds maximum %d", srcLen, lzoBlockMax)
+	}
+
+	if z.flags&lzoAdler32D != 0 {
+		if _, err := io.CopyN(io.Discard, z.r, 4); err != nil {
+			return fmt.Errorf("error skipping lzo decompressed checksum: %w", err)
+		}
+	}
+	if srcLen < dstLen && z.flags&lzoAdler32C != 0 {
+		if _, err := io.CopyN(io.Discard, z.r, 4); err != nil {
+			return fmt.Errorf("error skipping lzo compressed checksum: %w", err)
+		}
+	}
+
+	block := make([]byte, srcLen)
+	if _, err := io.ReadFull(z.r, block); err != nil {
+		return fmt.Errorf("error reading lzo block: %w", err)
+	}
+
+	if srcLen == dstLen {
+		z.buf, z.pos = block, 0
+		return nil
+	}
+
+	out, err := lzo1xDecompress(block, int(dstLen))
+	if err != nil {
+		return fmt.Errorf("error decompressing lzo block: %w", err)
+	}
+	z.buf, z.pos = out, 0
+	return nil
+}
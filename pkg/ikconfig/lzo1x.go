@@ -0,0 +1,215 @@
+package ikconfig
+
+import "fmt"
+
+// lzo1xDecompress is a small in-tree port of the public-domain LZO1X-1
+// decompression algorithm (as used by minilzo and the kernel's own
+// lib/lzo/lzo1x_decompress_safe.c), with bounds checks on every access
+// since src comes from an untrusted kernel image. dstLen is the expected
+// decompressed size, taken from the enclosing lzop block header.
+func lzo1xDecompress(src []byte, dstLen int) ([]byte, error) {
+	if len(src) == 0 {
+		return nil, fmt.Errorf("lzo1x: empty input")
+	}
+
+	dst := make([]byte, 0, dstLen)
+	ip := 0
+	var t int
+
+	need := func(n int) error {
+		if ip+n > len(src) {
+			return fmt.Errorf("lzo1x: truncated stream")
+		}
+		return nil
+	}
+	readByte := func() (byte, error) {
+		if err := need(1); err != nil {
+			return 0, err
+		}
+		b := src[ip]
+		ip++
+		return b, nil
+	}
+	copyLiteral := func(n int) error {
+		if err := need(n); err != nil {
+			return err
+		}
+		dst = append(dst, src[ip:ip+n]...)
+		ip += n
+		return nil
+	}
+	copyMatch := func(mPos, n int) error {
+		if mPos < 0 || mPos >= len(dst) {
+			return fmt.Errorf("lzo1x: match distance out of range")
+		}
+		for i := 0; i < n; i++ {
+			dst = append(dst, dst[mPos+i])
+		}
+		return nil
+	}
+	readVarLen := func(base int) (int, error) {
+		n := base
+		for {
+			b, err := readByte()
+			if err != nil {
+				return 0, err
+			}
+			if b != 0 {
+				n += int(b)
+				return n, nil
+			}
+			n += 255
+		}
+	}
+
+	b, err := readByte()
+	if err != nil {
+		return nil, err
+	}
+	if b > 17 {
+		t = int(b) - 17
+		if t < 4 {
+			goto matchNext
+		}
+		if err := copyLiteral(t); err != nil {
+			return nil, err
+		}
+		goto firstLiteralRun
+	}
+	t = int(b)
+
+loop:
+	if t >= 16 {
+		goto match
+	}
+	if t == 0 {
+		var err error
+		t, err = readVarLen(15)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := copyLiteral(t + 3); err != nil {
+		return nil, err
+	}
+
+firstLiteralRun:
+	{
+		b, err := readByte()
+		if err != nil {
+			return nil, err
+		}
+		t = int(b)
+	}
+	if t >= 16 {
+		goto match
+	}
+	{
+		b2, err := readByte()
+		if err != nil {
+			return nil, err
+		}
+		mPos := len(dst) - 2049 - (t >> 2) - (int(b2) << 2)
+		if err := copyMatch(mPos, 3); err != nil {
+			return nil, err
+		}
+		t &= 3
+	}
+	goto matchDone
+
+match:
+	{
+		var mPos, n, trailBits int
+		switch {
+		case t >= 64:
+			b2, err := readByte()
+			if err != nil {
+				return nil, err
+			}
+			mPos = len(dst) - 1 - ((t >> 2) & 7) - (int(b2) << 3)
+			n = (t >> 5) - 1
+			trailBits = t & 3
+
+		case t >= 32:
+			n = t & 31
+			if n == 0 {
+				var err error
+				n, err = readVarLen(31)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if err := need(2); err != nil {
+				return nil, err
+			}
+			dist := int(src[ip]) | int(src[ip+1])<<8
+			ip += 2
+			mPos = len(dst) - 1 - dist>>2
+			trailBits = dist & 3
+
+		case t >= 16:
+			n = t & 7
+			if n == 0 {
+				var err error
+				n, err = readVarLen(7)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if err := need(2); err != nil {
+				return nil, err
+			}
+			dist := int(src[ip]) | int(src[ip+1])<<8
+			ip += 2
+			mPos = len(dst) - (t&8)<<11 - dist>>2
+			if mPos == len(dst) {
+				if len(dst) != dstLen {
+					return nil, fmt.Errorf("lzo1x: short output, got %d want %d", len(dst), dstLen)
+				}
+				return dst, nil
+			}
+			mPos -= 0x4000
+			trailBits = dist & 3
+
+		default: // t < 16: short repeat match following a match's trailing literal
+			b2, err := readByte()
+			if err != nil {
+				return nil, err
+			}
+			mPos = len(dst) - 1 - (t >> 2) - (int(b2) << 2)
+			trailBits = t & 3
+		}
+		if err := copyMatch(mPos, n+2); err != nil {
+			return nil, err
+		}
+		t = trailBits
+	}
+
+matchDone:
+	if t == 0 {
+		goto matchEnd
+	}
+
+matchNext:
+	if err := copyLiteral(t); err != nil {
+		return nil, err
+	}
+	{
+		b, err := readByte()
+		if err != nil {
+			return nil, err
+		}
+		t = int(b)
+	}
+	goto match
+
+matchEnd:
+	{
+		b, err := readByte()
+		if err != nil {
+			return nil, err
+		}
+		t = int(b)
+	}
+	goto loop
+}
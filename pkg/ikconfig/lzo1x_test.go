@@ -0,0 +1,96 @@
+package ikconfig
+
+import (
+	"bytes"
+	"testing"
+)
+
+// These compressed fixtures exercise lzo1xDecompress directly rather than
+// through lzoBlockReader, since lzoBlockMax/lzoHeaderFixedLen framing is already
+// covered by test/ikconfig_test.go's table-driven tests once real vmlinuz
+// fixtures are available. They live in this package (rather than under
+// test/) because lzo1xDecompress is unexported.
+//
+// Each *Compressed slice was produced once by feeding the corresponding
+// *Raw content through github.com/rasky/go-lzo's LZO1X-1 encoder and is
+// checked in verbatim, so the test needs no LZO1X encoder of its own.
+// lzoFixtureShort drives the M2 match path (short back-references) and
+// lzoFixtureRepeat the M3 path (a medium, overlapping back-reference with
+// an extended length), which is exactly the class of bug fixed in
+// commit 9327489.
+var lzoFixtureShortRaw = []byte("CONFIG_IKCONFIG=y\nCONFIG_IKCONFIG_PROC=y\n")
+var lzoFixtureShortCompressed = []byte{
+	0x1a, 0x43, 0x4f, 0x4e, 0x46, 0x49, 0x47, 0x5f, 0x49, 0x4b, 0xa3, 0x01,
+	0x3d, 0x79, 0x0a, 0xa0, 0x01, 0x27, 0x44, 0x00, 0x02, 0x5f, 0x50, 0x52,
+	0x4f, 0x43, 0x58, 0x02, 0x11, 0x00, 0x00,
+}
+
+var lzoFixtureRepeatRaw = []byte("abcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdabcdwxyz")
+var lzoFixtureRepeatCompressed = []byte{
+	0x15, 0x61, 0x62, 0x63, 0x64, 0x20, 0x17, 0x0c, 0x00, 0x01, 0x77, 0x78,
+	0x79, 0x7a, 0x11, 0x00, 0x00,
+}
+
+var lzoFixtureLongCompressed = []byte{
+	0x28, 0x43, 0x4f, 0x4e, 0x46, 0x49, 0x47, 0x5f, 0x53, 0x4f, 0x4d, 0x45,
+	0x5f, 0x4f, 0x50, 0x54, 0x49, 0x4f, 0x4e, 0x3d, 0x79, 0x0a, 0x23, 0x20,
+	0xd8, 0x02, 0x0e, 0x4f, 0x54, 0x48, 0x45, 0x52, 0x20, 0x69, 0x73, 0x20,
+	0x6e, 0x6f, 0x74, 0x20, 0x73, 0x65, 0x74, 0x0a, 0xdc, 0x02, 0x20, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xe6, 0xb8, 0x00, 0x20, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0xe6, 0xb8, 0x00, 0x20, 0x00, 0x00, 0x00,
+	0x51, 0xb8, 0x00, 0x11, 0x00, 0x00,
+}
+
+func lzoFixtureLongRaw() []byte {
+	return bytes.Repeat([]byte("CONFIG_SOME_OPTION=y\n# CONFIG_OTHER is not set\n"), 107)
+}
+
+func TestLzo1xDecompress(t *testing.T) {
+	cases := []struct {
+		name       string
+		compressed []byte
+		want       []byte
+	}{
+		{"short", lzoFixtureShortCompressed, lzoFixtureShortRaw},
+		{"repeat", lzoFixtureRepeatCompressed, lzoFixtureRepeatRaw},
+		{"long", lzoFixtureLongCompressed, lzoFixtureLongRaw()},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := lzo1xDecompress(c.compressed, len(c.want))
+			if err != nil {
+				t.Fatalf("lzo1xDecompress: %v", err)
+			}
+			if !bytes.Equal(got, c.want) {
+				t.Errorf("lzo1xDecompress mismatch: got %d bytes, want %d bytes", len(got), len(c.want))
+			}
+		})
+	}
+}
+
+func TestLzo1xDecompressTruncated(t *testing.T) {
+	for i := 1; i < len(lzoFixtureShortCompressed); i++ {
+		if _, err := lzo1xDecompress(lzoFixtureShortCompressed[:i], len(lzoFixtureShortRaw)); err == nil {
+			t.Errorf("expected error decompressing truncated input of length %d, got nil", i)
+		}
+	}
+}
+
+func TestLzo1xDecompressBadDistance(t *testing.T) {
+	// Overwrite the high distance byte of the repeat match so it points before
+	// the start of dst; lzo1xDecompress must reject this rather than panic
+	// on an out-of-range slice index.
+	bad := append([]byte(nil), lzoFixtureRepeatCompressed...)
+	bad[8] = 0xff
+
+	if _, err := lzo1xDecompress(bad, len(lzoFixtureRepeatRaw)); err == nil {
+		t.Errorf("expected error decompressing corrupted match distance, got nil")
+	}
+}
+
+func TestLzo1xDecompressEmpty(t *testing.T) {
+	if _, err := lzo1xDecompress(nil, 0); err == nil {
+		t.Errorf("expected error decompressing empty input, got nil")
+	}
+}
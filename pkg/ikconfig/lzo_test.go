@@ -0,0 +1,93 @@
+package ikconfig
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+)
+
+// buildLzopStream frames raw, already-LZO1X-compressed block data behind a
+// real lzop container: the 9-byte magic, a full variable-length header
+// (flags, optional filename, header checksum), one <dstLen><srcLen><data>
+// block, then a zero-length terminator block. name, when non-empty, is
+// embedded in the header to exercise the variable-length filename field.
+func buildLzopStream(name string, flags uint32, dstLen, srcLen uint32, block []byte) []byte {
+	var buf bytes.Buffer
+	buf.Write(lzoDecompressor{}.Magic())
+
+	var fixed [lzoHeaderFixedLen]byte
+	binary.BigEndian.PutUint32(fixed[8:12], flags)
+	fixed[24] = byte(len(name))
+	buf.Write(fixed[:])
+	buf.WriteString(name)
+	buf.Write(make([]byte, 4)) // header checksum, unchecked by NewReader
+
+	var lenBuf [4]byte
+	binary.BigEndian.PutUint32(lenBuf[:], dstLen)
+	buf.Write(lenBuf[:])
+	binary.BigEndian.PutUint32(lenBuf[:], srcLen)
+	buf.Write(lenBuf[:])
+	buf.Write(block)
+
+	binary.BigEndian.PutUint32(lenBuf[:], 0)
+	buf.Write(lenBuf[:])
+	return buf.Bytes()
+}
+
+func TestLzoDecompressorStoredBlock(t *testing.T) {
+	data := []byte("CONFIG_IKCONFIG=y\n")
+
+	cases := []struct {
+		name     string
+		filename string
+	}{
+		{"empty filename", ""},
+		{"non-empty filename", "vmlinux"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			stream := buildLzopStream(c.filename, 0, uint32(len(data)), uint32(len(data)), data)
+
+			rc, err := lzoDecompressor{}.NewReader(bytes.NewReader(stream))
+			if err != nil {
+				t.Fatalf("NewReader: %v", err)
+			}
+			defer rc.Close()
+
+			got, err := io.ReadAll(rc)
+			if err != nil {
+				t.Fatalf("Read: %v", err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("got %q, want %q", got, data)
+			}
+		})
+	}
+}
+
+func TestLzoDecompressorCompressedBlock(t *testing.T) {
+	stream := buildLzopStream("", 0, uint32(len(lzoFixtureShortRaw)), uint32(len(lzoFixtureShortCompressed)), lzoFixtureShortCompressed)
+
+	rc, err := lzoDecompressor{}.NewReader(bytes.NewReader(stream))
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	defer rc.Close()
+
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if !bytes.Equal(got, lzoFixtureShortRaw) {
+		t.Errorf("got %q, want %q", got, lzoFixtureShortRaw)
+	}
+}
+
+func TestLzoDecompressorMagicIsFullLzopSignature(t *testing.T) {
+	want := []byte{0x89, 0x4c, 0x5a, 0x4f, 0x00, 0x0d, 0x0a, 0x1a, 0x0a}
+	if got := (lzoDecompressor{}).Magic(); !bytes.Equal(got, want) {
+		t.Errorf("Magic() = % x, want % x", got, want)
+	}
+}
@@ -0,0 +1,80 @@
+package ikconfig
+
+import (
+	"compress/gzip"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// procConfigGz is where the kernel exposes its own build config when
+// CONFIG_IKCONFIG_PROC=y.
+const procConfigGz = "/proc/config.gz"
+
+// FromRunningKernel returns the config of the currently booted kernel. It
+// tries, in order: reading /proc/config.gz directly; loading the
+// "configs" kernel module (present under /sys/module/configs once
+// loaded) to make that file appear on kernels built with
+// CONFIG_IKCONFIG=m rather than =y; and finally locating
+// /boot/vmlinuz-$(uname -r) and running the usual extraction pipeline on
+// it. This is the standard discovery dance for tooling that inspects the
+// currently-booted kernel, so callers don't have to reimplement it.
+func FromRunningKernel() (*KernelConfigMap, error) {
+	if configMap, err := parseProcConfigGz(); err == nil {
+		return configMap, nil
+	}
+
+	if err := loadConfigsModule(); err == nil {
+		if configMap, err := parseProcConfigGz(); err == nil {
+			return configMap, nil
+		}
+	}
+
+	release, err := unameRelease()
+	if err != nil {
+		return nil, fmt.Errorf("error determining running kernel release: %w", err)
+	}
+
+	path := "/boot/vmlinuz-" + release
+	kernel, err := NewKernelConfig(path, KERNEL_COMPRESSION_TYPE_UNKNOWN)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %q: %w", path, err)
+	}
+
+	return kernel.ParseKernelConfig()
+}
+
+func parseProcConfigGz() (*KernelConfigMap, error) {
+	f, err := os.Open(procConfigGz)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %q: %w", procConfigGz, err)
+	}
+	defer f.Close()
+
+	gzipReader, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("error creating gzip reader for %q: %w", procConfigGz, err)
+	}
+	defer gzipReader.Close()
+
+	return parseKernelConfigMap(gzipReader)
+}
+
+// loadConfigsModule loads the "configs" kernel module, which is what
+// exposes /proc/config.gz on kernels built with CONFIG_IKCONFIG=m.
+func loadConfigsModule() error {
+	out, err := exec.Command("modprobe", "configs").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error loading configs module: %w (%s)", err, out)
+	}
+	return nil
+}
+
+func unameRelease() (string, error) {
+	out, err := exec.Command("uname", "-r").Output()
+	if err != nil {
+		return "", fmt.Errorf("error running uname -r: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,160 @@
+package ikconfig
+
+import (
+	"fmt"
+	"io"
+)
+
+type MagicNotFound interface {
+	Error() string
+}
+
+type MagicNotFoundErr struct {
+	msg   string
+	magic []byte
+}
+
+func (e *MagicNotFoundErr) Error() string {
+	return fmt.Sprintf("magic string %v not found", e.magic)
+}
+
+// searchChunkSize bounds how much of a file is read into memory at once by
+// SearchReader/SearchAll, so a multi-hundred-MB vmlinux never has to be
+// read in one go.
+const searchChunkSize = 1 << 20 // 1 MiB
+
+// kmpTable builds the partial-match (failure function) table used by the
+// Knuth-Morris-Pratt search below.
+func kmpTable(needle []byte) []int {
+	table := make([]int, len(needle))
+	k := 0
+	for i := 1; i < len(needle); i++ {
+		for k > 0 && needle[k] != needle[i] {
+			k = table[k-1]
+		}
+		if needle[k] == needle[i] {
+			k++
+		}
+		table[i] = k
+	}
+	return table
+}
+
+// scanChunks runs a streaming KMP search for needle over the chunks
+// returned by next, which must be called until it returns io.EOF. Because
+// KMP carries its match state from one byte to the next, a match spanning
+// a chunk boundary is still found without having to buffer an explicit
+// overlap between calls. If limit > 0, scanning stops as soon as that many
+// matches have been found.
+func scanChunks(next func() ([]byte, error), needle []byte, limit int) ([]int64, error) {
+	if len(needle) == 0 {
+		return nil, fmt.Errorf("empty needle")
+	}
+
+	table := kmpTable(needle)
+	var matches []int64
+	var offset int64
+	k := 0
+
+	for {
+		chunk, err := next()
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		for _, b := range chunk {
+			for k > 0 && needle[k] != b {
+				k = table[k-1]
+			}
+			if needle[k] == b {
+				k++
+			}
+			offset++
+			if k == len(needle) {
+				matches = append(matches, offset-int64(len(needle)))
+				k = table[k-1]
+				if limit > 0 && len(matches) >= limit {
+					return matches, nil
+				}
+			}
+		}
+
+		if err == io.EOF {
+			return matches, nil
+		}
+	}
+}
+
+// chunksFromReaderAt returns a next-chunk function reading size bytes from
+// r, searchChunkSize at a time.
+func chunksFromReaderAt(r io.ReaderAt, size int64) func() ([]byte, error) {
+	var offset int64
+	buf := make([]byte, searchChunkSize)
+
+	return func() ([]byte, error) {
+		if offset >= size {
+			return nil, io.EOF
+		}
+
+		want := int64(len(buf))
+		if offset+want > size {
+			want = size - offset
+		}
+
+		n, err := r.ReadAt(buf[:want], offset)
+		offset += int64(n)
+		if err != nil && err != io.EOF {
+			return buf[:n], err
+		}
+		if offset >= size {
+			return buf[:n], io.EOF
+		}
+		return buf[:n], nil
+	}
+}
+
+// SearchReader returns the offset of the first occurrence of needle in r,
+// which must hold exactly size bytes.
+func SearchReader(r io.ReaderAt, size int64, needle []byte) (int64, error) {
+	matches, err := scanChunks(chunksFromReaderAt(r, size), needle, 1)
+	if err != nil {
+		return 0, err
+	}
+	if len(matches) == 0 {
+		return 0, &MagicNotFoundErr{msg: "magic string not found", magic: needle}
+	}
+	return matches[0], nil
+}
+
+// SearchAll returns the offsets of every occurrence of needle in r, which
+// must hold exactly size bytes. The IKCFG magic isn't guaranteed unique,
+// so callers like extract-ikconfig's bzImage handling may need every match
+// rather than just the first.
+func SearchAll(r io.ReaderAt, size int64, needle []byte) ([]int64, error) {
+	matches, err := scanChunks(chunksFromReaderAt(r, size), needle, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, &MagicNotFoundErr{msg: "magic string not found", magic: needle}
+	}
+	return matches, nil
+}
+
+// SearchBytes finds the offset of the first occurrence of b in the file at
+// path, without reading it into memory all at once: openForSearch mmaps
+// the file where the platform supports it, and otherwise falls back to a
+// chunked bufio.Reader (see search_unix.go / search_other.go).
+func SearchBytes(path string, b []byte) (uint, error) {
+	r, size, closeFn, err := openForSearch(path)
+	if err != nil {
+		return 0, fmt.Errorf("error opening file: %w", err)
+	}
+	defer closeFn()
+
+	offset, err := SearchReader(r, size, b)
+	if err != nil {
+		return 0, err
+	}
+	return uint(offset), nil
+}
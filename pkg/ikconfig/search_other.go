@@ -0,0 +1,65 @@
+//go:build !unix
+
+package ikconfig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+)
+
+// bufioReaderAt adapts a bufio.Reader to io.ReaderAt for platforms without
+// mmap support. ikconfig's own scanning always reads forward in
+// monotonically increasing chunks, so in practice this only ever
+// advances the buffer sequentially; an out-of-order ReadAt still works, it
+// just re-seeks the underlying file.
+type bufioReaderAt struct {
+	f   *os.File
+	br  *bufio.Reader
+	pos int64
+}
+
+func (b *bufioReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < b.pos {
+		if _, err := b.f.Seek(off, io.SeekStart); err != nil {
+			return 0, err
+		}
+		b.br.Reset(b.f)
+		b.pos = off
+	} else if off > b.pos {
+		if _, err := b.br.Discard(int(off - b.pos)); err != nil {
+			return 0, err
+		}
+		b.pos = off
+	}
+
+	n, err := io.ReadFull(b.br, p)
+	b.pos += int64(n)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func (b *bufioReaderAt) Close() error {
+	return b.f.Close()
+}
+
+// openForSearch opens path for a chunked bufio.Reader based scan, used on
+// platforms that don't support mmap (see search_unix.go for that path).
+func openForSearch(path string) (io.ReaderAt, int64, func() error, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("error opening file: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, nil, fmt.Errorf("error statting file: %w", err)
+	}
+
+	b := &bufioReaderAt{f: f, br: bufio.NewReaderSize(f, searchChunkSize)}
+	return b, info.Size(), b.Close, nil
+}
@@ -0,0 +1,63 @@
+//go:build unix
+
+package ikconfig
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// mmapReaderAt exposes a memory-mapped file as an io.ReaderAt backed
+// directly by the mapped pages, so scanning it never has to copy the whole
+// file into a separate buffer.
+type mmapReaderAt struct {
+	data []byte
+}
+
+func (m *mmapReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off >= int64(len(m.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, m.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (m *mmapReaderAt) Close() error {
+	if m.data == nil {
+		return nil
+	}
+	err := unix.Munmap(m.data)
+	m.data = nil
+	return err
+}
+
+// openForSearch mmaps path read-only and returns it as an io.ReaderAt.
+func openForSearch(path string) (io.ReaderAt, int64, func() error, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("error opening file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("error statting file: %w", err)
+	}
+	if info.Size() == 0 {
+		return &mmapReaderAt{}, 0, func() error { return nil }, nil
+	}
+
+	data, err := unix.Mmap(int(f.Fd()), 0, int(info.Size()), unix.PROT_READ, unix.MAP_SHARED)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("error mmapping file: %w", err)
+	}
+
+	m := &mmapReaderAt{data: data}
+	return m, info.Size(), m.Close, nil
+}
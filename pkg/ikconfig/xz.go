@@ -0,0 +1,30 @@
+package ikconfig
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ulikunitz/xz"
+)
+
+type xzDecompressor struct{}
+
+func (xzDecompressor) Name() string { return "xz" }
+
+func (xzDecompressor) Magic() []byte {
+	return []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+}
+
+func (xzDecompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	xzReader, err := xz.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("error creating xz reader: %w", err)
+	}
+	// github.com/ulikunitz/xz only exposes an io.Reader, so wrap it to
+	// satisfy Decompressor's io.ReadCloser return type.
+	return io.NopCloser(xzReader), nil
+}
+
+func init() {
+	RegisterDecompressor(KERNEL_COMPRESSION_TYPE_XZ, xzDecompressor{})
+}
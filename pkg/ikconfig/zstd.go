@@ -0,0 +1,37 @@
+package ikconfig
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+type zstdDecompressor struct{}
+
+func (zstdDecompressor) Name() string { return "zstd" }
+
+func (zstdDecompressor) Magic() []byte { return []byte{0x28, 0xb5, 0x2f, 0xfd} }
+
+func (zstdDecompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	zstdReader, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("error creating zstd reader: %w", err)
+	}
+	return zstdReadCloser{zstdReader}, nil
+}
+
+// zstdReadCloser adapts *zstd.Decoder, whose Close method returns no error,
+// to io.ReadCloser.
+type zstdReadCloser struct {
+	*zstd.Decoder
+}
+
+func (z zstdReadCloser) Close() error {
+	z.Decoder.Close()
+	return nil
+}
+
+func init() {
+	RegisterDecompressor(KERNEL_COMPRESSION_TYPE_ZSTD, zstdDecompressor{})
+}
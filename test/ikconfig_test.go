@@ -8,9 +8,14 @@ import (
 )
 
 func TestParseKonfig(t *testing.T) {
-	kernel, err := ikconfig.NewKernelConfig("testdata/vmlinuz-linux", ikconfig.KERNEL_COMPRESSION_TYPE_GZIP)
+	const path = "testdata/vmlinuz-linux"
+	if _, err := os.Stat(path); err != nil {
+		t.Skipf("fixture %q not available: %v", path, err)
+	}
+
+	kernel, err := ikconfig.NewKernelConfig(path, ikconfig.KERNEL_COMPRESSION_TYPE_GZIP)
 	if err != nil {
-		t.Errorf("Error creating new KernelConfig object: %v", err)
+		t.Fatalf("Error creating new KernelConfig object: %v", err)
 	}
 
 	if _, err := os.Stat(kernel.PathDecompressed()); err != nil {
@@ -19,17 +24,77 @@ func TestParseKonfig(t *testing.T) {
 
 	configMap, err := kernel.ParseKernelConfig()
 	if err != nil {
-		t.Errorf("Error parsing kernel config: %v", err)
+		t.Fatalf("Error parsing kernel config: %v", err)
 	}
 
 	val, err := configMap.Get("CONFIG_CC_VERSION_TEXT")
 	if err != nil {
 		t.Errorf("Error getting value from config map: %v", err)
 	}
+	if val.String == "" {
+		t.Errorf("expected CONFIG_CC_VERSION_TEXT to have a string value, got %+v", val)
+	}
+
+	if !configMap.Enabled("CONFIG_CC_VERSION_TEXT") {
+		t.Errorf("expected CONFIG_CC_VERSION_TEXT to be enabled")
+	}
 
 	t.Logf("CONFIG_CC_VERSION_TEXT: %v", val)
 }
 
+func TestParseKonfigCompressed(t *testing.T) {
+	cases := []struct {
+		name        string
+		path        string
+		compression ikconfig.KernelCompressionType
+	}{
+		{"gzip", "testdata/vmlinuz-gzip", ikconfig.KERNEL_COMPRESSION_TYPE_GZIP},
+		{"bzip2", "testdata/vmlinuz-bzip2", ikconfig.KERNEL_COMPRESSION_TYPE_BZIP2},
+		{"xz", "testdata/vmlinuz-xz", ikconfig.KERNEL_COMPRESSION_TYPE_XZ},
+		{"zstd", "testdata/vmlinuz-zstd", ikconfig.KERNEL_COMPRESSION_TYPE_ZSTD},
+		{"lzma", "testdata/vmlinuz-lzma", ikconfig.KERNEL_COMPRESSION_TYPE_LZMA},
+		{"lzo", "testdata/vmlinuz-lzo", ikconfig.KERNEL_COMPRESSION_TYPE_LZO},
+		{"lz4", "testdata/vmlinuz-lz4", ikconfig.KERNEL_COMPRESSION_TYPE_LZ4},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := os.Stat(c.path); err != nil {
+				t.Skipf("fixture %q not available: %v", c.path, err)
+			}
+
+			kernel, err := ikconfig.NewKernelConfig(c.path, c.compression)
+			if err != nil {
+				t.Fatalf("Error creating new KernelConfig object: %v", err)
+			}
+
+			configMap, err := kernel.ParseKernelConfig()
+			if err != nil {
+				t.Fatalf("Error parsing kernel config: %v", err)
+			}
+
+			if _, err := configMap.Get("CONFIG_CC_VERSION_TEXT"); err != nil {
+				t.Errorf("Error getting value from config map: %v", err)
+			}
+		})
+	}
+}
+
+func TestFromRunningKernel(t *testing.T) {
+	if _, err := os.Stat("/proc/config.gz"); err != nil {
+		t.Skipf("/proc/config.gz not available: %v", err)
+	}
+
+	configMap, err := ikconfig.FromRunningKernel()
+	if err != nil {
+		t.Fatalf("Error getting config of running kernel: %v", err)
+	}
+
+	if !configMap.Enabled("CONFIG_IKCONFIG") {
+		t.Errorf("expected CONFIG_IKCONFIG to be enabled on a kernel that exposes /proc/config.gz")
+	}
+}
+
 func TestFindMagic(t *testing.T) {
 	// the file zeroBin only contains 0 bytes, so this should fail
 	offset, err := ikconfig.SearchBytes("testdata/zero.bin", []byte{0x01})